@@ -0,0 +1,70 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Checkpoint persists which pages of a Job have completed, so a killed Run
+// can resume where it stopped instead of restarting from page 1 — critical
+// when TotalPages is in the thousands and each page takes seconds against a
+// flaky upstream database.
+type Checkpoint interface {
+	Save(jobID string, completed []int) error
+	Load(jobID string) ([]int, error)
+}
+
+// FileCheckpoint writes checkpoints as JSON next to a Job's profile output.
+type FileCheckpoint struct {
+	Dir string
+}
+
+// NewFileCheckpoint returns a FileCheckpoint that stores its JSON files in
+// dir.
+func NewFileCheckpoint(dir string) *FileCheckpoint {
+	return &FileCheckpoint{Dir: dir}
+}
+
+func (f *FileCheckpoint) path(jobID string) string {
+	return filepath.Join(f.Dir, jobID+".checkpoint.json")
+}
+
+func (f *FileCheckpoint) Save(jobID string, completed []int) error {
+	data, err := json.Marshal(completed)
+	if err != nil {
+		return fmt.Errorf("pipeline: marshaling checkpoint for %s: %w", jobID, err)
+	}
+	if err := os.WriteFile(f.path(jobID), data, 0o644); err != nil {
+		return fmt.Errorf("pipeline: writing checkpoint for %s: %w", jobID, err)
+	}
+	return nil
+}
+
+// completedPageNumbers converts a completed-page set into the sorted slice
+// Checkpoint.Save expects.
+func completedPageNumbers(completed map[int]bool) []int {
+	pages := make([]int, 0, len(completed))
+	for n := range completed {
+		pages = append(pages, n)
+	}
+	sort.Ints(pages)
+	return pages
+}
+
+func (f *FileCheckpoint) Load(jobID string) ([]int, error) {
+	data, err := os.ReadFile(f.path(jobID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pipeline: reading checkpoint for %s: %w", jobID, err)
+	}
+	var completed []int
+	if err := json.Unmarshal(data, &completed); err != nil {
+		return nil, fmt.Errorf("pipeline: parsing checkpoint for %s: %w", jobID, err)
+	}
+	return completed, nil
+}
@@ -0,0 +1,78 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// memCheckpoint is an in-memory Checkpoint for tests, avoiding disk I/O.
+type memCheckpoint struct {
+	mu        sync.Mutex
+	completed map[string][]int
+}
+
+func newMemCheckpoint(jobID string, completed []int) *memCheckpoint {
+	return &memCheckpoint{completed: map[string][]int{jobID: completed}}
+}
+
+func (m *memCheckpoint) Save(jobID string, completed []int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.completed[jobID] = completed
+	return nil
+}
+
+func (m *memCheckpoint) Load(jobID string) ([]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.completed[jobID], nil
+}
+
+// noopStager passes every page through unchanged.
+type noopStager struct{}
+
+func (noopStager) QueryPage(p *Page) error     { return nil }
+func (noopStager) PaginateQuery(p Page) string { return "" }
+
+func TestRunSkipsCheckpointedPages(t *testing.T) {
+	cp := newMemCheckpoint("job-1", []int{2})
+	j := Job{
+		JobID:        "job-1",
+		Checkpoint:   cp,
+		Pipeline:     []Stager{noopStager{}},
+		ProcessLimit: 2,
+		Pages: []Page{
+			{Number: 1},
+			{Number: 2},
+			{Number: 3},
+		},
+	}
+
+	results, _, err := j.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2 (page 2 should be skipped)", len(results))
+	}
+	for _, r := range results {
+		if r.Number == 2 {
+			t.Fatalf("page 2 was already checkpointed and should have been skipped")
+		}
+	}
+
+	completed, err := cp.Load("job-1")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	seen := map[int]bool{}
+	for _, n := range completed {
+		seen[n] = true
+	}
+	for _, want := range []int{1, 2, 3} {
+		if !seen[want] {
+			t.Fatalf("checkpoint missing page %d after Run: %v", want, completed)
+		}
+	}
+}
@@ -6,13 +6,13 @@
 package pipeline
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
 	"github.com/pkg/profile"
 	"log"
 	"errors"
 	"os"
-	"sync"
 )
 
 type Range struct {
@@ -27,10 +27,39 @@ type Job struct {
 	IndexField    string
 	RowType       Row
 
+	// Source, when set, switches the Job into cursor mode: Run and
+	// RunStreaming drive pagination by following Source's cursors, and
+	// Paginate skips the offset arithmetic below.
+	Source Source
+
+	// Filter is a go-bexpr expression (e.g. `status == "active" and age
+	// > 30`) evaluated against each row in ProcessPage; non-matching
+	// rows are dropped once, before any Stager sees the page. A Stager
+	// implementing Filterer does not replace this: its RowFilter runs
+	// afterward as an additional, narrowing filter over whatever Filter
+	// already let through.
+	Filter string
+
+	// Progress, when set, is notified as Run starts, completes each page,
+	// and finishes. It defaults to a no-op, so CLI tools can set
+	// NewBarProgress() while libraries pay nothing for it.
+	Progress Progress
+
+	// JobID identifies this Job to Checkpoint. It must be set for
+	// Checkpoint to take effect.
+	JobID string
+
+	// Checkpoint, when set alongside JobID, lets Run skip pages already
+	// completed by a prior, interrupted Run of the same job.
+	Checkpoint Checkpoint
+
 	TotalPages int
 	MaxRows  int
 
-	PageSize     int
+	PageSize int
+	// ProcessLimit is the default worker count for a Stager that doesn't
+	// implement StageConfig. RunStreaming also uses it directly to size
+	// its own (pre-per-stage) pool.
 	ProcessLimit int
 	PageRange    Range
 	Pages        []Page
@@ -45,7 +74,7 @@ type Stager interface {
 type JobRunner interface {
 	// should return error
 	Paginate(maxRows int) error
-	Run() ([]Page, error)
+	Run(ctx context.Context) ([]Page, *RunReport, error)
 	ProcessPage(p *Page, errc chan error) Page
 }
 
@@ -63,11 +92,27 @@ type Page struct {
 	IndexField string
 	Rows       []Row
 	Errors     []error
+	Filter     FilterStats
 }
 
 func (j Job) ProcessPage(p *Page, errc chan error) Page {
+	// The Job-level Filter applies once, before any stage sees the page —
+	// not once per stage, which would re-filter the already-filtered
+	// rows and inflate FilterStats on every pass.
+	if err := applyRowFilter(p, j.Filter); err != nil {
+		errc <- err
+	}
+
 	// Iterate through stages passing a Page.
 	for _, s := range j.Pipeline {
+		if f, ok := s.(Filterer); ok {
+			if override := f.RowFilter(); override != "" {
+				if err := applyRowFilter(p, override); err != nil {
+					errc <- err
+				}
+			}
+		}
+
 		err := s.QueryPage(p)
 		if err != nil {
 			errc <- err
@@ -77,10 +122,21 @@ func (j Job) ProcessPage(p *Page, errc chan error) Page {
 }
 
 func (j *Job) Paginate(maxRows int) error {
+	if j.Source != nil {
+		total, err := j.Source.TotalRows(context.Background())
+		if err != nil {
+			return err
+		}
+		j.MaxRows = total
+		j.TotalPages = 0
+		// Pages are produced lazily in Run by following the Source's
+		// cursor, so there is no fixed []Page to precompute here.
+		j.Pages = nil
+		return nil
+	}
+
 	if maxRows < 1 {
-		err := errors.New("You must specify")
-		log.Fatal(err)
-		return err
+		return errors.New("You must specify")
 	}
 	j.MaxRows = maxRows
 	if j.PageSize == 0 {
@@ -115,63 +171,134 @@ func (j *Job) Paginate(maxRows int) error {
 	return nil
 }
 
+// producerStage identifies PageErrors not raised by a Stager: those from
+// feeding pages in, from the Job-level Filter, and from Checkpoint.Save.
+const producerStage = -1
+
 // Consider returning a results object -- Stripping Rows of data
-func (j Job) Run() ([]Page, error) {
+//
+// Run honors ctx.Done() to cancel in-flight workers cleanly, and returns a
+// *RunReport aggregating every PageError alongside per-stage Metrics. The
+// returned error is an errors.Join of every PageError plus ctx.Err(), so
+// callers that only care whether anything failed can keep checking err !=
+// nil.
+func (j Job) Run(ctx context.Context) ([]Page, *RunReport, error) {
 	defer Proof().Stop()
-	// Paginator closes the done channel when it returns; it may do so before
-	// receiving all the values from c and errc.
-	done := make(chan struct{})
-	defer close(done)
-	errc := make(chan error, 1)
-	defer close(errc)
+
+	// errc is drained by its own goroutine for as long as the pipeline
+	// runs, rather than sized to a worst case and read back after the
+	// fact: a page can raise one error per stage, so pages*stages can
+	// exceed any fixed buffer (and j.Pages is nil in cursor mode), and a
+	// full buffer would block a worker forever with out never closing.
+	errc := make(chan PageError)
+	var pageErrs []PageError
+	errDone := make(chan struct{})
+	go func() {
+		defer close(errDone)
+		for pe := range errc {
+			pageErrs = append(pageErrs, pe)
+		}
+	}()
+
+	resuming := j.Checkpoint != nil && j.JobID != ""
+	// skip is read-only once the producer goroutine starts; completed is
+	// owned exclusively by the result loop below. Keeping them separate
+	// avoids a concurrent map read/write between the two goroutines.
+	skip := make(map[int]bool)
+	if resuming {
+		done, err := j.Checkpoint.Load(j.JobID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, n := range done {
+			skip[n] = true
+		}
+	}
+	completed := make(map[int]bool, len(skip))
+	for n := range skip {
+		completed[n] = true
+	}
+
 	pages := make(chan Page)
 	go func() {
 		defer close(pages)
+		if j.Source != nil {
+			// Cursor mode: follow nextCursor until the Source reports
+			// no more pages, rather than ranging over a precomputed
+			// []Page.
+			cursor := ""
+			for {
+				page, next, err := j.Source.NextPage(ctx, cursor)
+				if err != nil {
+					errc <- PageError{Page: page.Number, Stage: producerStage, Err: err}
+					return
+				}
+				if !skip[page.Number] {
+					select {
+					case pages <- page:
+					case <-ctx.Done():
+						return
+					}
+				}
+				if !j.Source.HasNext(next) {
+					return
+				}
+				cursor = next
+			}
+		}
 		for _, page := range j.Pages {
+			if skip[page.Number] {
+				continue
+			}
 			select {
 			case pages <- page:
-			case <-done:
+			case <-ctx.Done():
 				return
 			}
 		}
 	}()
 
-	c := make(chan Page)
-
-	var wg sync.WaitGroup
-	wg.Add(j.ProcessLimit)
+	metrics := newMetrics(len(j.Pipeline))
+	c := j.runStages(ctx, pages, errc, metrics)
 
-	for i := 0; i < j.ProcessLimit; i++ {
-		go func() {
-			for page := range pages {
-				select {
-				case c <- j.ProcessPage(&page, errc):
-				case <-done:
-					return
-				}
-			}
-			wg.Done()
-		}()
+	progress := j.Progress
+	if progress == nil {
+		progress = noopProgress{}
 	}
-
-	go func() {
-		wg.Wait()
-		close(c)
-	}()
+	progress.Start(len(j.Pages))
 
 	var results []Page
-
 	for r := range c {
 		results = append(results, r)
-		log.Println("Compare :: ", r.Number)
+		progress.Increment(r)
+
+		if resuming {
+			completed[r.Number] = true
+			if err := j.Checkpoint.Save(j.JobID, completedPageNumbers(completed)); err != nil {
+				errc <- PageError{Page: r.Number, Stage: producerStage, Err: err}
+			}
+		}
 	}
-	errc <- nil
-	if err := <-errc; err != nil {
-		fmt.Println("Process errors :: ", err)
-		return nil, err
+	progress.Finish()
+
+	// c only closes once every stage's workers (and the producer) have
+	// exited, so nothing can still be sending on errc at this point.
+	close(errc)
+	<-errDone
+
+	report := &RunReport{Metrics: *metrics, Errors: pageErrs}
+	var errs []error
+	for _, pe := range pageErrs {
+		errs = append(errs, pe)
+	}
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+	}
+	if len(errs) > 0 {
+		return results, report, errors.Join(errs...)
 	}
 
-	return results, nil
+	return results, report, nil
 }
 
 func Proof() interface{ Stop() } {
@@ -181,11 +308,11 @@ func Proof() interface{ Stop() } {
 	return profile.Start(profile.ProfilePath("./"))
 }
 
-func writetocsv(name string, header [][]string, rows [][]string) {
+func writetocsv(name string, header [][]string, rows [][]string) error {
 	rows = append(header, rows...)
 	file, err := os.Create(name)
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("pipeline: creating %s: %w", name, err)
 	}
 	defer file.Close()
 
@@ -193,9 +320,9 @@ func writetocsv(name string, header [][]string, rows [][]string) {
 	defer writer.Flush()
 
 	for _, value := range rows {
-		err := writer.Write(value)
-		if err != nil {
-			log.Fatal(err)
+		if err := writer.Write(value); err != nil {
+			return fmt.Errorf("pipeline: writing csv row: %w", err)
 		}
 	}
+	return nil
 }
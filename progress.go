@@ -0,0 +1,47 @@
+package pipeline
+
+import "github.com/cheggaaa/pb/v3"
+
+// Progress reports coarse-grained progress for a long-running Job, invoked
+// from Run as pages complete. It replaces the ad-hoc log.Println that used
+// to sit in Run's hot loop.
+type Progress interface {
+	Start(total int)
+	Increment(page Page)
+	Finish()
+}
+
+// noopProgress is the zero-value default, so setting Job.Progress is
+// optional and costs nothing when pipeline is embedded in a service.
+type noopProgress struct{}
+
+func (noopProgress) Start(int)      {}
+func (noopProgress) Increment(Page) {}
+func (noopProgress) Finish()        {}
+
+// BarProgress renders a CLI progress bar via github.com/cheggaaa/pb, the
+// default choice for command-line tools built on pipeline.
+type BarProgress struct {
+	bar *pb.ProgressBar
+}
+
+// NewBarProgress returns a Progress that draws a terminal progress bar.
+func NewBarProgress() *BarProgress {
+	return &BarProgress{}
+}
+
+func (b *BarProgress) Start(total int) {
+	b.bar = pb.StartNew(total)
+}
+
+func (b *BarProgress) Increment(Page) {
+	if b.bar != nil {
+		b.bar.Increment()
+	}
+}
+
+func (b *BarProgress) Finish() {
+	if b.bar != nil {
+		b.bar.Finish()
+	}
+}
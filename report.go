@@ -0,0 +1,26 @@
+package pipeline
+
+// PageError records an error raised by a specific stage while processing a
+// specific page, so callers can tell which page and which stage need
+// attention instead of a single opaque error for the whole Run.
+type PageError struct {
+	Page  int
+	Stage int
+	Err   error
+}
+
+func (e PageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e PageError) Unwrap() error {
+	return e.Err
+}
+
+// RunReport aggregates the outcome of a Run: per-stage throughput via
+// Metrics, and every PageError encountered along the way, keyed by page
+// number and stage index.
+type RunReport struct {
+	Metrics Metrics
+	Errors  []PageError
+}
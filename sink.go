@@ -0,0 +1,251 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Sink receives completed Pages as they exit the Pipeline, so a caller can
+// stream results to disk or stdout instead of accumulating them in
+// memory like Run does.
+type Sink interface {
+	Write(p Page) error
+	Close() error
+}
+
+// csvSink streams rows to name as they arrive, a sibling to the
+// buffer-everything writetocsv.
+type csvSink struct {
+	file *os.File
+	w    *csv.Writer
+}
+
+// NewCSVSink opens name and, if header is non-empty, writes it as the first
+// CSV record.
+func NewCSVSink(name string, header []string) (Sink, error) {
+	file, err := os.Create(name)
+	if err != nil {
+		return nil, fmt.Errorf("pipeline: creating %s: %w", name, err)
+	}
+	sink := &csvSink{file: file, w: csv.NewWriter(file)}
+	if len(header) > 0 {
+		if err := sink.w.Write(header); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("pipeline: writing csv header: %w", err)
+		}
+	}
+	return sink, nil
+}
+
+func (s *csvSink) Write(p Page) error {
+	for _, row := range p.Rows {
+		values := row.Unpack()
+		record := make([]string, len(values))
+		for i, v := range values {
+			record[i] = fmt.Sprint(v)
+		}
+		if err := s.w.Write(record); err != nil {
+			return fmt.Errorf("pipeline: writing csv row: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *csvSink) Close() error {
+	s.w.Flush()
+	if err := s.w.Error(); err != nil {
+		return err
+	}
+	return s.file.Close()
+}
+
+// jsonSink streams rows into a single top-level JSON array: `[` on the
+// first row, comma-separated rows across page boundaries (each row encoded
+// as the JSON array of its Unpack() values), `]` on Close. This turns N pages into one valid JSON array instead of N arrays
+// concatenated.
+type jsonSink struct {
+	w     io.WriteCloser
+	enc   *json.Encoder
+	mu    sync.Mutex
+	wrote bool
+}
+
+// NewJSONSink wraps w as a Sink that writes a single JSON array.
+func NewJSONSink(w io.WriteCloser) Sink {
+	return &jsonSink{w: w, enc: json.NewEncoder(w)}
+}
+
+func (s *jsonSink) Write(p Page) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, row := range p.Rows {
+		if s.wrote {
+			if _, err := io.WriteString(s.w, ","); err != nil {
+				return err
+			}
+		} else {
+			if _, err := io.WriteString(s.w, "["); err != nil {
+				return err
+			}
+		}
+		if err := s.enc.Encode(row.Unpack()); err != nil {
+			return fmt.Errorf("pipeline: encoding row: %w", err)
+		}
+		s.wrote = true
+	}
+	return nil
+}
+
+func (s *jsonSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.wrote {
+		if _, err := io.WriteString(s.w, "["); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(s.w, "]"); err != nil {
+		return err
+	}
+	return s.w.Close()
+}
+
+// WriteJSON streams pages to name as a single JSON array, rather than
+// buffering the whole result set the way writetocsv does.
+func WriteJSON(name string, pages <-chan Page) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("pipeline: creating %s: %w", name, err)
+	}
+
+	sink := NewJSONSink(file)
+	for p := range pages {
+		if err := sink.Write(p); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return sink.Close()
+}
+
+// RunStreaming runs the Job like Run, but pushes each page to sink as soon
+// as it exits the Pipeline instead of accumulating results in memory, and
+// closes sink once every page has been written. If a stage, sink.Write or
+// ctx reports an error, that error is returned and sink is left open.
+func (j Job) RunStreaming(ctx context.Context, sink Sink) error {
+	defer Proof().Stop()
+
+	// stop lets an early sink.Write failure cut the run short without
+	// waiting for every page to flow through; it is safe to call more
+	// than once.
+	done := make(chan struct{})
+	var stopOnce sync.Once
+	stop := func() { stopOnce.Do(func() { close(done) }) }
+	defer stop()
+
+	// errc is drained by its own goroutine for as long as workers run,
+	// rather than via a size-1 channel handshake after the fact: with
+	// ProcessPage able to send one error per stage per page, that
+	// handshake fills up and deadlocks on the first error.
+	errc := make(chan error)
+	var errs []error
+	errDone := make(chan struct{})
+	go func() {
+		defer close(errDone)
+		for err := range errc {
+			errs = append(errs, err)
+		}
+	}()
+
+	pages := make(chan Page)
+	go func() {
+		defer close(pages)
+		if j.Source != nil {
+			// Cursor mode: follow nextCursor the same way Run does,
+			// rather than ranging over j.Pages, which Paginate leaves
+			// nil for a cursor-mode Job.
+			cursor := ""
+			for {
+				page, next, err := j.Source.NextPage(ctx, cursor)
+				if err != nil {
+					errc <- err
+					return
+				}
+				select {
+				case pages <- page:
+				case <-ctx.Done():
+					return
+				case <-done:
+					return
+				}
+				if !j.Source.HasNext(next) {
+					return
+				}
+				cursor = next
+			}
+		}
+		for _, page := range j.Pages {
+			select {
+			case pages <- page:
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	c := make(chan Page)
+	var wg sync.WaitGroup
+	wg.Add(j.ProcessLimit)
+	for i := 0; i < j.ProcessLimit; i++ {
+		go func() {
+			defer wg.Done()
+			for page := range pages {
+				select {
+				case c <- j.ProcessPage(&page, errc):
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(c)
+		close(errc)
+	}()
+
+	var writeErr error
+	for r := range c {
+		if writeErr != nil {
+			continue
+		}
+		if err := sink.Write(r); err != nil {
+			writeErr = fmt.Errorf("pipeline: writing page %d: %w", r.Number, err)
+			stop()
+		}
+	}
+
+	<-errDone
+
+	if writeErr != nil {
+		return writeErr
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return sink.Close()
+}
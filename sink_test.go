@@ -0,0 +1,134 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// testRow is a minimal Packer for exercising sinks without a real database
+// row type.
+type testRow struct {
+	ID int
+}
+
+func (r testRow) Unpack() []interface{} {
+	return []interface{}{r.ID}
+}
+
+func (r testRow) Pack(values []interface{}) Packer {
+	id, _ := values[0].(int)
+	return testRow{ID: id}
+}
+
+// nopWriteCloser adapts a bytes.Buffer to io.WriteCloser for NewJSONSink,
+// which always closes its underlying writer.
+type nopWriteCloser struct {
+	*bytes.Buffer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestJSONSinkWritesSingleArrayAcrossPages(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONSink(nopWriteCloser{&buf})
+
+	pages := []Page{
+		{Number: 1, Rows: []Row{testRow{ID: 1}, testRow{ID: 2}}},
+		{Number: 2, Rows: []Row{testRow{ID: 3}}},
+	}
+	for _, p := range pages {
+		if err := sink.Write(p); err != nil {
+			t.Fatalf("Write(%d): %v", p.Number, err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Each row is encoded as the JSON array of its Unpack() values.
+	var got [][]int
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not a single JSON array: %v (body: %s)", err, buf.String())
+	}
+	want := []int{1, 2, 3}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if len(got[i]) != 1 || got[i][0] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestJSONSinkEmptyResult(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONSink(nopWriteCloser{&buf})
+
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if buf.String() != "[]" {
+		t.Fatalf("got %q, want %q", buf.String(), "[]")
+	}
+}
+
+// fakeSource is a cursor-mode Source that serves pages from a fixed slice,
+// one per cursor value "0", "1", ....
+type fakeSource struct {
+	pages []Page
+}
+
+func (f *fakeSource) TotalRows(ctx context.Context) (int, error) {
+	return len(f.pages), nil
+}
+
+func (f *fakeSource) NextPage(ctx context.Context, cursor string) (Page, string, error) {
+	i := 0
+	if cursor != "" {
+		i = cursorIndex(cursor)
+	}
+	return f.pages[i], cursorFor(i + 1), nil
+}
+
+func (f *fakeSource) HasNext(cursor string) bool {
+	return cursorIndex(cursor) < len(f.pages)
+}
+
+func cursorFor(i int) string {
+	return string(rune('0' + i))
+}
+
+func cursorIndex(cursor string) int {
+	if cursor == "" {
+		return 0
+	}
+	return int(cursor[0] - '0')
+}
+
+func TestRunStreamingDrivesSource(t *testing.T) {
+	j := Job{
+		Source: &fakeSource{pages: []Page{
+			{Number: 1, Rows: []Row{testRow{ID: 1}}},
+			{Number: 2, Rows: []Row{testRow{ID: 2}}},
+		}},
+		ProcessLimit: 2,
+	}
+
+	var buf bytes.Buffer
+	sink := NewJSONSink(nopWriteCloser{&buf})
+
+	if err := j.RunStreaming(context.Background(), sink); err != nil {
+		t.Fatalf("RunStreaming: %v", err)
+	}
+
+	var got [][]int
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not a single JSON array: %v (body: %s)", err, buf.String())
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %v, want 2 rows from Source", got)
+	}
+}
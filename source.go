@@ -0,0 +1,27 @@
+package pipeline
+
+import "context"
+
+// Source abstracts how a Job discovers and walks pages of data. The default
+// mode (no Source set) keeps the existing offset arithmetic in Paginate,
+// which only works for monotonically indexed tables with a known row count.
+// Setting Source switches a Job into cursor mode: pages are produced by
+// following NextPage's returned cursor until HasNext reports false, the same
+// way a REST client walks a "Link: rel=next" header. This lets callers plug
+// in REST APIs, GraphQL cursors, or custom DB drivers without knowing the
+// total row count up front.
+type Source interface {
+	// TotalRows reports the total number of rows available, when known.
+	// Cursor-based sources that cannot know this ahead of time should
+	// return 0.
+	TotalRows(ctx context.Context) (int, error)
+
+	// NextPage fetches the page following cursor. An empty cursor means
+	// "first page". It returns the fetched page and the cursor for the
+	// page that follows it.
+	NextPage(ctx context.Context, cursor string) (Page, string, error)
+
+	// HasNext reports whether cursor, as returned by NextPage, refers to
+	// another page still to be fetched.
+	HasNext(cursor string) bool
+}
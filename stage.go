@@ -0,0 +1,193 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// StageConfig is implemented by a Stager that wants explicit control over
+// its own concurrency and rate, rather than inheriting Job.ProcessLimit.
+// This lets a slow, expensive stage (an HTTP-backed enricher, say) be
+// throttled independently of fast in-memory ones.
+type StageConfig interface {
+	// Concurrency is the number of workers Run should dedicate to this
+	// stage. Values less than 1 fall back to Job.ProcessLimit (at least
+	// one worker).
+	Concurrency() int
+
+	// RatePerSecond caps how many pages per second this stage processes,
+	// enforced with a token bucket. Zero or negative disables the limit.
+	RatePerSecond() float64
+}
+
+// StageMetrics captures per-stage throughput, error counts, and queue
+// depth.
+type StageMetrics struct {
+	Processed int
+	Errors    int
+
+	// QueueDepth is the deepest backlog observed waiting to enter this
+	// stage, sampled as workers dequeue pages from their buffered input
+	// channel. It is a high-water mark, not a live reading. The first
+	// stage reads Run's unbuffered page channel unless Job.Filter is set,
+	// so its QueueDepth stays 0 in that case.
+	QueueDepth int
+}
+
+// Metrics aggregates StageMetrics across a Pipeline, keyed by stage index,
+// as returned from Run. It holds its lock by pointer so Metrics itself
+// stays safe to copy and return by value.
+type Metrics struct {
+	Stages map[int]StageMetrics
+	mu     *sync.Mutex
+}
+
+func newMetrics(stages int) *Metrics {
+	return &Metrics{Stages: make(map[int]StageMetrics, stages), mu: &sync.Mutex{}}
+}
+
+func (m *Metrics) recordProcessed(stage int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.Stages[stage]
+	s.Processed++
+	m.Stages[stage] = s
+}
+
+func (m *Metrics) recordError(stage int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.Stages[stage]
+	s.Errors++
+	m.Stages[stage] = s
+}
+
+func (m *Metrics) recordQueueDepth(stage, depth int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.Stages[stage]
+	if depth > s.QueueDepth {
+		s.QueueDepth = depth
+	}
+	m.Stages[stage] = s
+}
+
+// runStages wires j.Pipeline into a chain of per-stage worker pools, each
+// with its own concurrency and rate limit, so pages flow stage to stage
+// over channels and back-pressure propagates naturally from a slow stage to
+// the ones feeding it. Workers honor ctx.Done() so Run can cancel in-flight
+// stages cleanly.
+//
+// Job.Filter and any per-Stager RowFilter override run as filtering steps
+// in the same chain, not inside QueryPage, so Run drops non-matching rows
+// the same way ProcessPage does for the serial/streaming path.
+func (j Job) runStages(ctx context.Context, in <-chan Page, errc chan<- PageError, metrics *Metrics) <-chan Page {
+	out := in
+	if j.Filter != "" {
+		out = j.runFilterStage(ctx, producerStage, j.Filter, out, errc)
+	}
+	for i, s := range j.Pipeline {
+		if f, ok := s.(Filterer); ok {
+			if override := f.RowFilter(); override != "" {
+				out = j.runFilterStage(ctx, i, override, out, errc)
+			}
+		}
+		out = j.runStage(ctx, i, s, out, errc, metrics)
+	}
+	return out
+}
+
+// runFilterStage applies expr to every page flowing through, once, via the
+// same applyRowFilter helper ProcessPage uses.
+func (j Job) runFilterStage(ctx context.Context, index int, expr string, in <-chan Page, errc chan<- PageError) <-chan Page {
+	// Buffered for the same reason runStage's out is: it keeps the next
+	// stage's QueueDepth sampling meaningful.
+	out := make(chan Page, 1)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case page, ok := <-in:
+				if !ok {
+					return
+				}
+				if err := applyRowFilter(&page, expr); err != nil {
+					errc <- PageError{Page: page.Number, Stage: index, Err: err}
+				}
+				select {
+				case out <- page:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func (j Job) runStage(ctx context.Context, index int, s Stager, in <-chan Page, errc chan<- PageError, metrics *Metrics) <-chan Page {
+	// A Stager that doesn't implement StageConfig inherits Job.ProcessLimit,
+	// the same knob callers already used for parallelism before stages ran
+	// as independent pools.
+	concurrency := j.ProcessLimit
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var limiter *rate.Limiter
+	if cfg, ok := s.(StageConfig); ok {
+		if c := cfg.Concurrency(); c > 0 {
+			concurrency = c
+		}
+		if r := cfg.RatePerSecond(); r > 0 {
+			limiter = rate.NewLimiter(rate.Limit(r), 1)
+		}
+	}
+
+	// Buffered so QueueDepth has something meaningful to sample: an
+	// unbuffered channel never holds a backlog for len() to observe.
+	out := make(chan Page, concurrency)
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case page, ok := <-in:
+					if !ok {
+						return
+					}
+					metrics.recordQueueDepth(index, len(in))
+					if limiter != nil {
+						if err := limiter.Wait(ctx); err != nil {
+							errc <- PageError{Page: page.Number, Stage: index, Err: err}
+							return
+						}
+					}
+					if err := s.QueryPage(&page); err != nil {
+						errc <- PageError{Page: page.Number, Stage: index, Err: err}
+						metrics.recordError(index)
+					} else {
+						metrics.recordProcessed(index)
+					}
+					select {
+					case out <- page:
+					case <-ctx.Done():
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
@@ -0,0 +1,77 @@
+package pipeline
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingStager counts how many pages it has processed, to verify
+// runStages delivers every page exactly once through the worker pool.
+type countingStager struct {
+	seen int32
+}
+
+func (s *countingStager) QueryPage(p *Page) error {
+	atomic.AddInt32(&s.seen, 1)
+	return nil
+}
+
+func (s *countingStager) PaginateQuery(p Page) string { return "" }
+
+func TestRunStagesProcessesAllPages(t *testing.T) {
+	stager := &countingStager{}
+	j := Job{Pipeline: []Stager{stager}, ProcessLimit: 3}
+
+	in := make(chan Page)
+	errc := make(chan PageError, 10)
+	metrics := newMetrics(len(j.Pipeline))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := j.runStages(ctx, in, errc, metrics)
+
+	const total = 5
+	go func() {
+		defer close(in)
+		for i := 1; i <= total; i++ {
+			in <- Page{Number: i}
+		}
+	}()
+
+	got := 0
+	for range out {
+		got++
+	}
+	if got != total {
+		t.Fatalf("got %d pages out, want %d", got, total)
+	}
+	if n := atomic.LoadInt32(&stager.seen); n != total {
+		t.Fatalf("stage saw %d pages, want %d", n, total)
+	}
+}
+
+func TestRunStagesCancelStopsIdleWorkers(t *testing.T) {
+	stager := &countingStager{}
+	j := Job{Pipeline: []Stager{stager}, ProcessLimit: 2}
+
+	in := make(chan Page) // never written to: workers sit idle on <-in
+	errc := make(chan PageError, 10)
+	metrics := newMetrics(len(j.Pipeline))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := j.runStages(ctx, in, errc, metrics)
+
+	cancel()
+
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatalf("expected out to close with no pages after cancel, got a page")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("out did not close within 1s of ctx cancellation")
+	}
+}